@@ -25,6 +25,7 @@ func TokenizeString(in string) []string {
 }
 
 // Tokenize Text splits on any non-alphanumerical and lowercases the words
+// (Unicode lower case mapping, not full case folding)
 func TokenizeText(in string) []string {
 	parts := strings.FieldsFunc(in, func(c rune) bool {
 		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
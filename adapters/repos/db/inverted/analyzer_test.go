@@ -77,6 +77,24 @@ func TestAnalyzer(t *testing.T) {
 				},
 			})
 		})
+
+		t.Run("with numbers", func(t *testing.T) {
+			res := a.Text("Model 350, model X-350")
+			assert.ElementsMatch(t, res, []Countable{
+				{
+					Data:          []byte("model"),
+					TermFrequency: float64(2) / 5,
+				},
+				{
+					Data:          []byte("350"),
+					TermFrequency: float64(2) / 5,
+				},
+				{
+					Data:          []byte("x"),
+					TermFrequency: float64(1) / 5,
+				},
+			})
+		})
 	})
 
 	t.Run("with string", func(t *testing.T) {
@@ -13,6 +13,7 @@ package inverted
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -36,8 +37,8 @@ type propValuePair struct {
 	children      []*propValuePair
 }
 
-func (pv *propValuePair) fetchDocIDs(s *Searcher, limit int,
-	tolerateDuplicates bool) error {
+func (pv *propValuePair) fetchDocIDs(ctx context.Context, s *Searcher,
+	limit int, tolerateDuplicates bool) error {
 	if pv.operator.OnValue() {
 		id := helpers.BucketFromPropNameLSM(pv.prop)
 		if pv.prop == "id" {
@@ -51,10 +52,11 @@ func (pv *propValuePair) fetchDocIDs(s *Searcher, limit int,
 			// a nil bucket is ok for a WithinGeoRange filter, as this query is not
 			// served by the inverted index, but propagated to a secondary index in
 			// .docPointers()
-			return errors.Errorf("bucket for prop %s not found - is it indexed?", pv.prop)
+			return errors.Wrapf(ErrBucketNotFound,
+				"bucket for prop %s not found - is it indexed?", pv.prop)
 		}
 
-		pointers, err := s.docPointers(id, b, limit, pv, tolerateDuplicates)
+		pointers, err := s.docPointers(ctx, id, b, limit, pv, tolerateDuplicates)
 		if err != nil {
 			return err
 		}
@@ -62,11 +64,15 @@ func (pv *propValuePair) fetchDocIDs(s *Searcher, limit int,
 		pv.docIDs = pointers
 	} else {
 		for i, child := range pv.children {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
 			// Explicitly set the limit to 0 (=unlimited) as this is a nested filter,
 			// otherwise we run into situations where each subfilter on their own
 			// runs into the limit, possibly yielding in "less than limit" results
 			// after merging.
-			err := child.fetchDocIDs(s, 0, tolerateDuplicates)
+			err := child.fetchDocIDs(ctx, s, 0, tolerateDuplicates)
 			if err != nil {
 				return errors.Wrapf(err, "nested child %d", i)
 			}
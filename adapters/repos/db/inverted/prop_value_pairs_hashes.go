@@ -38,7 +38,7 @@ func (pv *propValuePair) cacheable() bool {
 	}
 }
 
-func (pv *propValuePair) fetchHashes(s *Searcher) error {
+func (pv *propValuePair) fetchHashes(ctx context.Context, s *Searcher) error {
 	if pv.operator.OnValue() {
 		if pv.prop == "id" {
 			pv.prop = helpers.PropertyNameID
@@ -48,7 +48,8 @@ func (pv *propValuePair) fetchHashes(s *Searcher) error {
 		bucketName := helpers.HashBucketFromPropNameLSM(pv.prop)
 		b := s.store.Bucket(bucketName)
 		if b == nil && pv.operator != filters.OperatorWithinGeoRange {
-			return errors.Errorf("hash bucket for prop %s not found - is it indexed?", pv.prop)
+			return errors.Wrapf(ErrBucketNotFound,
+				"hash bucket for prop %s not found - is it indexed?", pv.prop)
 		}
 
 		var hash []byte
@@ -59,7 +60,7 @@ func (pv *propValuePair) fetchHashes(s *Searcher) error {
 				return err
 			}
 		} else {
-			hash, err = pv.hashForNonEqualOp(s.store, b)
+			hash, err = pv.hashForNonEqualOp(ctx, s.store, b)
 			if err != nil {
 				return err
 			}
@@ -70,7 +71,7 @@ func (pv *propValuePair) fetchHashes(s *Searcher) error {
 	} else {
 		checksums := make([][]byte, len(pv.children))
 		for i, child := range pv.children {
-			if err := child.fetchHashes(s); err != nil {
+			if err := child.fetchHashes(ctx, s); err != nil {
 				return errors.Wrap(err, "child filter")
 			}
 
@@ -83,26 +84,27 @@ func (pv *propValuePair) fetchHashes(s *Searcher) error {
 	return nil
 }
 
-func (pv *propValuePair) hashForNonEqualOp(store *lsmkv.Store,
-	hashBucket *lsmkv.Bucket) ([]byte, error) {
+func (pv *propValuePair) hashForNonEqualOp(ctx context.Context,
+	store *lsmkv.Store, hashBucket *lsmkv.Bucket) ([]byte, error) {
 	bucketName := helpers.BucketFromPropNameLSM(pv.prop)
 	propBucket := store.Bucket(bucketName)
 	if propBucket == nil && pv.operator != filters.OperatorWithinGeoRange {
-		return nil, errors.Errorf("bucket for prop %s not found - is it indexed?", pv.prop)
+		return nil, errors.Wrapf(ErrBucketNotFound,
+			"bucket for prop %s not found - is it indexed?", pv.prop)
 	}
 
 	if pv.hasFrequency {
-		return pv.hashForNonEqualOpWithFrequency(propBucket, hashBucket)
+		return pv.hashForNonEqualOpWithFrequency(ctx, propBucket, hashBucket)
 	}
-	return pv.hashForNonEqualOpWithoutFrequency(propBucket, hashBucket)
+	return pv.hashForNonEqualOpWithoutFrequency(ctx, propBucket, hashBucket)
 }
 
-func (pv *propValuePair) hashForNonEqualOpWithoutFrequency(propBucket,
-	hashBucket *lsmkv.Bucket) ([]byte, error) {
+func (pv *propValuePair) hashForNonEqualOpWithoutFrequency(ctx context.Context,
+	propBucket, hashBucket *lsmkv.Bucket) ([]byte, error) {
 	rr := NewRowReader(propBucket, pv.value, pv.operator, true)
 
 	var keys [][]byte
-	if err := rr.Read(context.TODO(), func(k []byte, ids [][]byte) (bool, error) {
+	if err := rr.Read(ctx, func(k []byte, ids [][]byte) (bool, error) {
 		keys = append(keys, k)
 		return true, nil
 	}); err != nil {
@@ -121,12 +123,12 @@ func (pv *propValuePair) hashForNonEqualOpWithoutFrequency(propBucket,
 	return combineChecksums(hashes, pv.operator), nil
 }
 
-func (pv *propValuePair) hashForNonEqualOpWithFrequency(propBucket,
-	hashBucket *lsmkv.Bucket) ([]byte, error) {
+func (pv *propValuePair) hashForNonEqualOpWithFrequency(ctx context.Context,
+	propBucket, hashBucket *lsmkv.Bucket) ([]byte, error) {
 	rr := NewRowReaderFrequency(propBucket, pv.value, pv.operator, true)
 
 	var keys [][]byte
-	if err := rr.Read(context.TODO(), func(k []byte, ids []lsmkv.MapPair) (bool, error) {
+	if err := rr.Read(ctx, func(k []byte, ids []lsmkv.MapPair) (bool, error) {
 		keys = append(keys, k)
 		return true, nil
 	}); err != nil {
@@ -0,0 +1,38 @@
+//                           _       _
+// __      _____  __ ___   ___  __ _| |_ ___
+// \ \ /\ / / _ \/ _` \ \ / / |/ _` | __/ _ \
+//  \ V  V /  __/ (_| |\ V /| | (_| | ||  __/
+//   \_/\_/ \___|\__,_| \_/ |_|\__,_|\__\___|
+//
+//  Copyright © 2016 - 2022 SeMI Technologies B.V. All rights reserved.
+//
+//  CONTACT: hello@semi.technology
+//
+
+package inverted
+
+import (
+	"context"
+	"testing"
+
+	"github.com/semi-technologies/weaviate/entities/filters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchDocIDs_ContextCancelled(t *testing.T) {
+	pv := propValuePair{
+		operator: filters.OperatorAnd,
+		children: []*propValuePair{
+			{prop: "foo", value: []byte("bar"), operator: filters.OperatorEqual},
+			{prop: "foo", value: []byte("baz"), operator: filters.OperatorEqual},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the searcher is never reached, as the cancelled context is detected
+	// before the first child is fetched
+	err := pv.fetchDocIDs(ctx, &Searcher{}, 0, false)
+	assert.Equal(t, context.Canceled, err)
+}
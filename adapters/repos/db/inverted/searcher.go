@@ -12,7 +12,6 @@
 package inverted
 
 import (
-	"bytes"
 	"context"
 	"encoding/binary"
 	"fmt"
@@ -46,6 +45,10 @@ type DeletedDocIDChecker interface {
 	Contains(id uint64) bool
 }
 
+// ErrBucketNotFound is returned when a bucket required to serve a search is
+// not present in the store, e.g. because the property is not indexed
+var ErrBucketNotFound = errors.New("bucket not found")
+
 func NewSearcher(store *lsmkv.Store, schema schema.Schema,
 	rowCache cacher, propIndices propertyspecific.Indices,
 	classSearcher ClassSearcher, deletedDocIDs DeletedDocIDChecker) *Searcher {
@@ -63,7 +66,7 @@ func NewSearcher(store *lsmkv.Store, schema schema.Schema,
 func (f *Searcher) Object(ctx context.Context, limit int,
 	filter *filters.LocalFilter, additional additional.Properties,
 	className schema.ClassName) ([]*storobj.Object, error) {
-	pv, err := f.extractPropValuePair(filter.Root, className)
+	pv, err := f.extractPropValuePair(ctx, filter.Root, className)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +74,7 @@ func (f *Searcher) Object(ctx context.Context, limit int,
 	var out []*storobj.Object
 	// we assume that when retrieving objects, we can not tolerate duplicates as
 	// they would have a direct impact on the user
-	if err := pv.fetchDocIDs(f, limit, false); err != nil {
+	if err := pv.fetchDocIDs(ctx, f, limit, false); err != nil {
 		return nil, errors.Wrap(err, "fetch doc ids for prop/value pair")
 	}
 
@@ -96,22 +99,33 @@ func (f *Searcher) Object(ctx context.Context, limit int,
 
 func (f *Searcher) objectsByDocID(ids []uint64,
 	additional additional.Properties) ([]*storobj.Object, error) {
-	out := make([]*storobj.Object, len(ids))
-
 	bucket := f.store.Bucket(helpers.ObjectsBucketLSM)
 	if bucket == nil {
-		return nil, errors.Errorf("objects bucket not found")
+		return nil, errors.Wrap(ErrBucketNotFound, "objects bucket not found")
 	}
 
+	return ObjectsByDocID(bucket, ids, additional)
+}
+
+// ObjectsByDocID resolves the doc ids to full objects using the doc id
+// secondary index of the objects bucket. The order of ids is preserved,
+// objects that no longer exist (e.g. deleted) are skipped.
+func ObjectsByDocID(bucket *lsmkv.Bucket, ids []uint64,
+	additional additional.Properties) ([]*storobj.Object, error) {
+	out := make([]*storobj.Object, len(ids))
+
+	// i is the write position in out, it only advances on found objects
 	i := 0
 
-	for _, id := range ids {
-		keyBuf := bytes.NewBuffer(nil)
-		binary.Write(keyBuf, binary.LittleEndian, &id)
-		docIDBytes := keyBuf.Bytes()
+	// the key is only used for the duration of the lookup, so a single buffer
+	// can be reused for all ids
+	docIDBytes := make([]byte, 8)
+
+	for pos, id := range ids {
+		binary.LittleEndian.PutUint64(docIDBytes, id)
 		res, err := bucket.GetBySecondary(0, docIDBytes)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrapf(err, "get object with doc id %d", id)
 		}
 
 		if res == nil {
@@ -120,7 +134,8 @@ func (f *Searcher) objectsByDocID(ids []uint64,
 
 		unmarshalled, err := storobj.FromBinaryOptional(res, additional)
 		if err != nil {
-			return nil, errors.Wrapf(err, "unmarshal data object at position %d", i)
+			return nil, errors.Wrapf(err,
+				"unmarshal data object with doc id %d at position %d", id, pos)
 		}
 
 		out[i] = unmarshalled
@@ -142,7 +157,7 @@ func (f *Searcher) objectsByDocID(ids []uint64,
 // had the shortest distance
 func (f *Searcher) DocIDs(ctx context.Context, filter *filters.LocalFilter,
 	additional additional.Properties, className schema.ClassName) (helpers.AllowList, error) {
-	pv, err := f.extractPropValuePair(filter.Root, className)
+	pv, err := f.extractPropValuePair(ctx, filter.Root, className)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +165,7 @@ func (f *Searcher) DocIDs(ctx context.Context, filter *filters.LocalFilter,
 	cacheable := pv.cacheable()
 	if !cacheable {
 	} else {
-		if err := pv.fetchHashes(f); err != nil {
+		if err := pv.fetchHashes(ctx, f); err != nil {
 			return nil, errors.Wrap(err, "fetch row hashes to check for cach eligibility")
 		}
 
@@ -162,7 +177,7 @@ func (f *Searcher) DocIDs(ctx context.Context, filter *filters.LocalFilter,
 
 	// when building an allow list (which is a set anyway) we can skip the costly
 	// deduplication, as it doesn't matter
-	if err := pv.fetchDocIDs(f, -1, true); err != nil {
+	if err := pv.fetchDocIDs(ctx, f, -1, true); err != nil {
 		return nil, errors.Wrap(err, "fetch doc ids for prop/value pair")
 	}
 
@@ -188,7 +203,8 @@ func (f *Searcher) DocIDs(ctx context.Context, filter *filters.LocalFilter,
 	return out, nil
 }
 
-func (fs *Searcher) extractPropValuePair(filter *filters.Clause,
+func (fs *Searcher) extractPropValuePair(ctx context.Context,
+	filter *filters.Clause,
 	className schema.ClassName) (*propValuePair, error) {
 	var out propValuePair
 	if filter.Operands != nil {
@@ -196,7 +212,7 @@ func (fs *Searcher) extractPropValuePair(filter *filters.Clause,
 		out.children = make([]*propValuePair, len(filter.Operands))
 
 		for i, clause := range filter.Operands {
-			child, err := fs.extractPropValuePair(&clause, className)
+			child, err := fs.extractPropValuePair(ctx, &clause, className)
 			if err != nil {
 				return nil, errors.Wrapf(err, "nested clause at pos %d", i)
 			}
@@ -209,7 +225,7 @@ func (fs *Searcher) extractPropValuePair(filter *filters.Clause,
 	// on value or non-nested filter
 	props := filter.On.Slice()
 	if len(props) != 1 {
-		return fs.extractReferenceFilter(filter, className)
+		return fs.extractReferenceFilter(ctx, filter, className)
 	}
 	// we are on a value element
 
@@ -237,9 +253,8 @@ func (fs *Searcher) extractPropValuePair(filter *filters.Clause,
 		filter.Operator)
 }
 
-func (fs *Searcher) extractReferenceFilter(filter *filters.Clause,
-	className schema.ClassName) (*propValuePair, error) {
-	ctx := context.TODO()
+func (fs *Searcher) extractReferenceFilter(ctx context.Context,
+	filter *filters.Clause, className schema.ClassName) (*propValuePair, error) {
 	return newRefFilterExtractor(fs.classSearcher, filter, className, fs.schema).Do(ctx)
 }
 
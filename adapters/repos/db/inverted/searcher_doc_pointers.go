@@ -24,37 +24,46 @@ import (
 	"github.com/semi-technologies/weaviate/entities/filters"
 )
 
-func (fs *Searcher) docPointers(prop string, b *lsmkv.Bucket, limit int,
-	pv *propValuePair, tolerateDuplicates bool) (docPointers, error) {
+func (fs *Searcher) docPointers(ctx context.Context, prop string,
+	b *lsmkv.Bucket, limit int, pv *propValuePair,
+	tolerateDuplicates bool) (docPointers, error) {
 	if pv.operator == filters.OperatorWithinGeoRange {
 		// geo props cannot be served by the inverted index and they require an
 		// external index. So, instead of trying to serve this chunk of the filter
 		// request internally, we can pass it to an external geo index
-		return fs.docPointersGeo(pv)
+		return fs.docPointersGeo(ctx, pv)
 	} else {
 		// all other operators perform operations on the inverted index which we
 		// can serve directly
-		return fs.docPointersInverted(prop, b, limit, pv, tolerateDuplicates)
+		return fs.docPointersInverted(ctx, prop, b, limit, pv, tolerateDuplicates)
 	}
 }
 
-func (fs *Searcher) docPointersInverted(prop string, b *lsmkv.Bucket, limit int,
-	pv *propValuePair, tolerateDuplicates bool) (docPointers, error) {
+func (fs *Searcher) docPointersInverted(ctx context.Context, prop string,
+	b *lsmkv.Bucket, limit int, pv *propValuePair,
+	tolerateDuplicates bool) (docPointers, error) {
 	if pv.hasFrequency {
-		return fs.docPointersInvertedFrequency(prop, b, limit, pv, tolerateDuplicates)
+		return fs.docPointersInvertedFrequency(ctx, prop, b, limit, pv, tolerateDuplicates)
 	}
 
-	return fs.docPointersInvertedNoFrequency(prop, b, limit, pv, tolerateDuplicates)
+	return fs.docPointersInvertedNoFrequency(ctx, prop, b, limit, pv, tolerateDuplicates)
 }
 
-func (fs *Searcher) docPointersInvertedNoFrequency(prop string, b *lsmkv.Bucket, limit int,
-	pv *propValuePair, tolerateDuplicates bool) (docPointers, error) {
+func (fs *Searcher) docPointersInvertedNoFrequency(ctx context.Context, prop string,
+	b *lsmkv.Bucket, limit int, pv *propValuePair,
+	tolerateDuplicates bool) (docPointers, error) {
+	hashBucket := fs.store.Bucket(helpers.HashBucketFromPropNameLSM(pv.prop))
+	if hashBucket == nil {
+		return docPointers{}, errors.Wrapf(ErrBucketNotFound,
+			"no hash bucket for prop '%s' found", pv.prop)
+	}
+
 	rr := NewRowReader(b, pv.value, pv.operator, false)
 
 	var pointers docPointers
 	var hashes [][]byte
 
-	if err := rr.Read(context.TODO(), func(k []byte, ids [][]byte) (bool, error) {
+	if err := rr.Read(ctx, func(k []byte, ids [][]byte) (bool, error) {
 		currentDocIDs := make([]docPointer, len(ids))
 		for i, asBytes := range ids {
 			currentDocIDs[i].id = binary.LittleEndian.Uint64(asBytes)
@@ -63,11 +72,6 @@ func (fs *Searcher) docPointersInvertedNoFrequency(prop string, b *lsmkv.Bucket,
 		pointers.count += uint64(len(ids))
 		pointers.docIDs = append(pointers.docIDs, currentDocIDs...)
 
-		hashBucket := fs.store.Bucket(helpers.HashBucketFromPropNameLSM(pv.prop))
-		if hashBucket == nil {
-			return false, errors.Errorf("no hash bucket for prop '%s' found", pv.prop)
-		}
-
 		currHash, err := hashBucket.Get(k)
 		if err != nil {
 			return false, errors.Wrap(err, "get hash")
@@ -91,17 +95,32 @@ func (fs *Searcher) docPointersInvertedNoFrequency(prop string, b *lsmkv.Bucket,
 	return pointers, nil
 }
 
-func (fs *Searcher) docPointersInvertedFrequency(prop string, b *lsmkv.Bucket, limit int,
-	pv *propValuePair, tolerateDuplicates bool) (docPointers, error) {
+func (fs *Searcher) docPointersInvertedFrequency(ctx context.Context, prop string,
+	b *lsmkv.Bucket, limit int, pv *propValuePair,
+	tolerateDuplicates bool) (docPointers, error) {
+	hashBucket := fs.store.Bucket(helpers.HashBucketFromPropNameLSM(pv.prop))
+	if hashBucket == nil {
+		return docPointers{}, errors.Wrapf(ErrBucketNotFound,
+			"no hash bucket for prop '%s' found", pv.prop)
+	}
+
 	rr := NewRowReaderFrequency(b, pv.value, pv.operator, false)
 
 	var pointers docPointers
 	var hashes [][]byte
 
-	if err := rr.Read(context.TODO(), func(k []byte, pairs []lsmkv.MapPair) (bool, error) {
+	if err := rr.Read(ctx, func(k []byte, pairs []lsmkv.MapPair) (bool, error) {
 		currentDocIDs := make([]docPointer, len(pairs))
 		// beforePairs := time.Now()
 		for i, pair := range pairs {
+			// both the doc id and the frequency are 8 bytes, anything else would
+			// indicate a corrupt row and would otherwise lead to a panic
+			if len(pair.Key) != 8 || len(pair.Value) != 8 {
+				return false, errors.Errorf("prop '%s': row %q: invalid map pair at "+
+					"pos %d: expected 8 byte key and value, got %d and %d bytes",
+					pv.prop, k, i, len(pair.Key), len(pair.Value))
+			}
+
 			currentDocIDs[i].id = binary.LittleEndian.Uint64(pair.Key)
 			freqBits := binary.LittleEndian.Uint64(pair.Value)
 			freq := math.Float64frombits(freqBits)
@@ -116,11 +135,6 @@ func (fs *Searcher) docPointersInvertedFrequency(prop string, b *lsmkv.Bucket, l
 			pointers.docIDs = currentDocIDs
 		}
 
-		hashBucket := fs.store.Bucket(helpers.HashBucketFromPropNameLSM(pv.prop))
-		if b == nil {
-			return false, errors.Errorf("no hash bucket for prop '%s' found", pv.prop)
-		}
-
 		// use retrieved k instead of pv.value - they are typically the same, but
 		// not on a like operator with wildcard where we only had a partial match
 		currHash, err := hashBucket.Get(k)
@@ -146,14 +160,14 @@ func (fs *Searcher) docPointersInvertedFrequency(prop string, b *lsmkv.Bucket, l
 	return pointers, nil
 }
 
-func (fs *Searcher) docPointersGeo(pv *propValuePair) (docPointers, error) {
+func (fs *Searcher) docPointersGeo(ctx context.Context,
+	pv *propValuePair) (docPointers, error) {
 	propIndex, ok := fs.propIndices.ByProp(pv.prop)
 	out := docPointers{}
 	if !ok {
 		return out, nil
 	}
 
-	ctx := context.TODO() // TODO: pass through instead of spawning new
 	res, err := propIndex.GeoIndex.WithinRange(ctx, *pv.valueGeoRange)
 	if err != nil {
 		return out, errors.Wrapf(err, "geo index range search on prop %q", pv.prop)
@@ -0,0 +1,479 @@
+//                           _       _
+// __      _____  __ ___   ___  __ _| |_ ___
+// \ \ /\ / / _ \/ _` \ \ / / |/ _` | __/ _ \
+//  \ V  V /  __/ (_| |\ V /| | (_| | ||  __/
+//   \_/\_/ \___|\__,_| \_/ |_|\__,_|\__\___|
+//
+//  Copyright © 2016 - 2022 SeMI Technologies B.V. All rights reserved.
+//
+//  CONTACT: hello@semi.technology
+//
+
+//go:build integrationTest
+// +build integrationTest
+
+package inverted
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/semi-technologies/weaviate/adapters/repos/db/helpers"
+	"github.com/semi-technologies/weaviate/adapters/repos/db/lsmkv"
+	"github.com/semi-technologies/weaviate/entities/additional"
+	"github.com/semi-technologies/weaviate/entities/filters"
+	"github.com/semi-technologies/weaviate/entities/models"
+	"github.com/semi-technologies/weaviate/entities/schema"
+	"github.com/semi-technologies/weaviate/entities/storobj"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Searcher_ObjectsByDocID(t *testing.T) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer func() {
+		err := os.RemoveAll(dirName)
+		fmt.Println(err)
+	}()
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(t, err)
+	defer store.Shutdown(context.Background())
+
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.ObjectsBucketLSM, lsmkv.WithStrategy(lsmkv.StrategyReplace),
+		lsmkv.WithSecondaryIndicies(1)))
+	bucket := store.Bucket(helpers.ObjectsBucketLSM)
+
+	putObject := func(t *testing.T, docID uint64, data []byte) {
+		docIDBytes := make([]byte, 8)
+		binary.LittleEndian.PutUint64(docIDBytes, docID)
+		require.Nil(t, bucket.Put([]byte(fmt.Sprintf("key-%d", docID)), data,
+			lsmkv.WithSecondaryKey(0, docIDBytes)))
+	}
+
+	t.Run("import objects", func(t *testing.T) {
+		for _, docID := range []uint64{1, 3, 5} {
+			obj := storobj.FromObject(&models.Object{
+				Class: "Foo",
+				ID:    strfmt.UUID(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", docID)),
+			}, nil)
+			obj.SetDocID(docID)
+			data, err := obj.MarshalBinary()
+			require.Nil(t, err)
+			putObject(t, docID, data)
+		}
+	})
+
+	searcher := NewSearcher(store, schema.Schema{}, nil, nil, nil, nil)
+
+	t.Run("present and missing doc ids interleaved", func(t *testing.T) {
+		res, err := searcher.objectsByDocID([]uint64{5, 2, 1, 4, 3},
+			additional.Properties{})
+		require.Nil(t, err)
+
+		var docIDs []uint64
+		for _, obj := range res {
+			docIDs = append(docIDs, obj.DocID())
+		}
+		assert.Equal(t, []uint64{5, 1, 3}, docIDs)
+	})
+
+	t.Run("corrupt object", func(t *testing.T) {
+		putObject(t, 7, []byte{0x02})
+
+		_, err := searcher.objectsByDocID([]uint64{2, 1, 7, 3},
+			additional.Properties{})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "doc id 7 at position 2")
+	})
+}
+
+func Test_Searcher_MissingBuckets(t *testing.T) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer func() {
+		err := os.RemoveAll(dirName)
+		fmt.Println(err)
+	}()
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(t, err)
+	defer store.Shutdown(context.Background())
+
+	// only the inverted buckets are created, the matching hash buckets and the
+	// objects bucket are missing
+	freqPropName := "inverted-with-frequency-without-hash"
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.BucketFromPropNameLSM(freqPropName),
+		lsmkv.WithStrategy(lsmkv.StrategyMapCollection)))
+	freqBucket := store.Bucket(helpers.BucketFromPropNameLSM(freqPropName))
+	for _, pair := range idsToBinaryMapValues([]uint64{1, 2, 3}) {
+		require.Nil(t, freqBucket.MapSet([]byte("foo"), pair))
+	}
+
+	noFreqPropName := "inverted-without-frequency-without-hash"
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.BucketFromPropNameLSM(noFreqPropName),
+		lsmkv.WithStrategy(lsmkv.StrategySetCollection)))
+	intValue, err := LexicographicallySortableInt64(7)
+	require.Nil(t, err)
+	docIDBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(docIDBytes, 1)
+	require.Nil(t, store.Bucket(helpers.BucketFromPropNameLSM(noFreqPropName)).
+		SetAdd(intValue, [][]byte{docIDBytes}))
+
+	searcher := NewSearcher(store, schema.Schema{}, nil, nil, nil, nil)
+	filter := func(propName string, value interface{},
+		dt schema.DataType) *filters.LocalFilter {
+		return &filters.LocalFilter{
+			Root: &filters.Clause{
+				Operator: filters.OperatorEqual,
+				On: &filters.Path{
+					Class:    "Foo",
+					Property: schema.PropertyName(propName),
+				},
+				Value: &filters.Value{
+					Value: value,
+					Type:  dt,
+				},
+			},
+		}
+	}
+
+	t.Run("missing hash bucket with frequency", func(t *testing.T) {
+		_, err := searcher.Object(context.Background(), 10,
+			filter(freqPropName, "foo", schema.DataTypeString),
+			additional.Properties{}, "Foo")
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrBucketNotFound))
+		assert.Contains(t, err.Error(),
+			fmt.Sprintf("no hash bucket for prop '%s' found", freqPropName))
+	})
+
+	t.Run("missing hash bucket without frequency", func(t *testing.T) {
+		_, err := searcher.Object(context.Background(), 10,
+			filter(noFreqPropName, 7, schema.DataTypeInt),
+			additional.Properties{}, "Foo")
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrBucketNotFound))
+		assert.Contains(t, err.Error(),
+			fmt.Sprintf("no hash bucket for prop '%s' found", noFreqPropName))
+	})
+
+	t.Run("missing inverted bucket", func(t *testing.T) {
+		_, err := searcher.Object(context.Background(), 10,
+			filter("not-indexed", "foo", schema.DataTypeString),
+			additional.Properties{}, "Foo")
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrBucketNotFound))
+		assert.Contains(t, err.Error(),
+			"bucket for prop not-indexed not found - is it indexed?")
+	})
+
+	t.Run("missing objects bucket", func(t *testing.T) {
+		_, err := searcher.objectsByDocID([]uint64{1}, additional.Properties{})
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrBucketNotFound))
+		assert.Contains(t, err.Error(), "objects bucket not found")
+	})
+}
+
+func Test_Searcher_MalformedMapPairs(t *testing.T) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer func() {
+		err := os.RemoveAll(dirName)
+		fmt.Println(err)
+	}()
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(t, err)
+	defer store.Shutdown(context.Background())
+
+	propName := "inverted-with-frequency"
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.BucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategyMapCollection)))
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.HashBucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategyReplace)))
+	bucket := store.Bucket(helpers.BucketFromPropNameLSM(propName))
+	require.Nil(t, store.Bucket(helpers.HashBucketFromPropNameLSM(propName)).
+		Put([]byte("short-value"), make([]byte, 8)))
+	require.Nil(t, store.Bucket(helpers.HashBucketFromPropNameLSM(propName)).
+		Put([]byte("short-key"), make([]byte, 8)))
+
+	require.Nil(t, bucket.MapSet([]byte("short-value"), lsmkv.MapPair{
+		Key:   make([]byte, 8),
+		Value: make([]byte, 4),
+	}))
+	require.Nil(t, bucket.MapSet([]byte("short-key"), lsmkv.MapPair{
+		Key:   make([]byte, 2),
+		Value: make([]byte, 8),
+	}))
+
+	searcher := NewSearcher(store, schema.Schema{}, nil, nil, nil, nil)
+
+	for _, value := range []string{"short-value", "short-key"} {
+		t.Run(value, func(t *testing.T) {
+			filter := &filters.LocalFilter{
+				Root: &filters.Clause{
+					Operator: filters.OperatorEqual,
+					On: &filters.Path{
+						Class:    "Foo",
+						Property: schema.PropertyName(propName),
+					},
+					Value: &filters.Value{
+						Value: value,
+						Type:  schema.DataTypeString,
+					},
+				},
+			}
+
+			_, err := searcher.Object(context.Background(), 10, filter,
+				additional.Properties{}, "Foo")
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), fmt.Sprintf("prop '%s'", propName))
+			assert.Contains(t, err.Error(), fmt.Sprintf("row %q", value))
+			assert.Contains(t, err.Error(), "invalid map pair")
+		})
+	}
+}
+
+func Test_Searcher_ContextCancelled(t *testing.T) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer func() {
+		err := os.RemoveAll(dirName)
+		fmt.Println(err)
+	}()
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(t, err)
+	defer store.Shutdown(context.Background())
+
+	propName := "age"
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.BucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategySetCollection)))
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.HashBucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategyReplace)))
+	bucket := store.Bucket(helpers.BucketFromPropNameLSM(propName))
+	hashBucket := store.Bucket(helpers.HashBucketFromPropNameLSM(propName))
+
+	t.Run("import int props", func(t *testing.T) {
+		for docID := uint64(1); docID <= 5; docID++ {
+			value, err := LexicographicallySortableInt64(int64(docID))
+			require.Nil(t, err)
+			docIDBytes := make([]byte, 8)
+			binary.LittleEndian.PutUint64(docIDBytes, docID)
+			require.Nil(t, bucket.SetAdd(value, [][]byte{docIDBytes}))
+
+			hash := make([]byte, 8)
+			binary.LittleEndian.PutUint64(hash, rand.Uint64())
+			require.Nil(t, hashBucket.Put(value, hash))
+		}
+	})
+
+	searcher := NewSearcher(store, schema.Schema{}, newRowCacherSpy(), nil, nil, nil)
+	filter := func(operator filters.Operator) *filters.LocalFilter {
+		return &filters.LocalFilter{
+			Root: &filters.Clause{
+				Operator: operator,
+				On: &filters.Path{
+					Class:    "Foo",
+					Property: schema.PropertyName(propName),
+				},
+				Value: &filters.Value{
+					Value: 2,
+					Type:  schema.DataTypeInt,
+				},
+			},
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("objects with equal filter", func(t *testing.T) {
+		_, err := searcher.Object(ctx, 10, filter(filters.OperatorEqual),
+			additional.Properties{}, "Foo")
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("doc ids with range filter", func(t *testing.T) {
+		// the range filter is cacheable, so the cancellation must already stop
+		// the row hash scan that runs before any doc ids are fetched
+		_, err := searcher.DocIDs(ctx, filter(filters.OperatorGreaterThan),
+			additional.Properties{}, "Foo")
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Contains(t, err.Error(), "fetch row hashes")
+	})
+
+	t.Run("doc ids with range filter and live context", func(t *testing.T) {
+		res, err := searcher.DocIDs(context.Background(),
+			filter(filters.OperatorGreaterThan), additional.Properties{}, "Foo")
+		require.Nil(t, err)
+
+		expected := helpers.AllowList{}
+		expected.Insert(3)
+		expected.Insert(4)
+		expected.Insert(5)
+		assert.Equal(t, expected, res)
+	})
+}
+
+func Test_Searcher_NumericTermsInText(t *testing.T) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer func() {
+		err := os.RemoveAll(dirName)
+		fmt.Println(err)
+	}()
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(t, err)
+	defer store.Shutdown(context.Background())
+
+	propName := "description"
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.BucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategyMapCollection)))
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.HashBucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategyReplace)))
+	bucket := store.Bucket(helpers.BucketFromPropNameLSM(propName))
+	hashBucket := store.Bucket(helpers.HashBucketFromPropNameLSM(propName))
+
+	docs := map[uint64]string{
+		1: "Model X-350, now in red",
+		2: "Model X-3500 with a bigger battery",
+		3: "model 350 refurbished",
+	}
+
+	t.Run("import text props", func(t *testing.T) {
+		for docID, text := range docs {
+			for _, item := range NewAnalyzer().Text(text) {
+				// rows are written with the same layout as
+				// Shard.extendInvertedIndexItemWithFrequencyLSM
+				buf := make([]byte, 16)
+				binary.LittleEndian.PutUint64(buf[:8], docID)
+				binary.LittleEndian.PutUint64(buf[8:], uint64(item.TermFrequency))
+				require.Nil(t, bucket.MapSet(item.Data, lsmkv.MapPair{
+					Key:   buf[:8],
+					Value: buf[8:],
+				}))
+
+				hash := make([]byte, 8)
+				binary.LittleEndian.PutUint64(hash, rand.Uint64())
+				require.Nil(t, hashBucket.Put(item.Data, hash))
+			}
+		}
+	})
+
+	searcher := NewSearcher(store, schema.Schema{}, newRowCacherSpy(), nil, nil, nil)
+
+	t.Run("equal filter on numeric term", func(t *testing.T) {
+		res, err := searcher.DocIDs(context.Background(), &filters.LocalFilter{
+			Root: &filters.Clause{
+				Operator: filters.OperatorEqual,
+				On: &filters.Path{
+					Class:    "Foo",
+					Property: schema.PropertyName(propName),
+				},
+				Value: &filters.Value{
+					Value: "model 350",
+					Type:  schema.DataTypeText,
+				},
+			},
+		}, additional.Properties{}, "Foo")
+		require.Nil(t, err)
+
+		expected := helpers.AllowList{}
+		expected.Insert(1)
+		expected.Insert(3)
+		assert.Equal(t, expected, res)
+	})
+}
+
+func BenchmarkObjectsByDocID(b *testing.B) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer os.RemoveAll(dirName)
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(b, err)
+	defer store.Shutdown(context.Background())
+
+	require.Nil(b, store.CreateOrLoadBucket(context.Background(),
+		helpers.ObjectsBucketLSM, lsmkv.WithStrategy(lsmkv.StrategyReplace),
+		lsmkv.WithSecondaryIndicies(1)))
+	bucket := store.Bucket(helpers.ObjectsBucketLSM)
+
+	ids := make([]uint64, 1000)
+	for i := range ids {
+		docID := uint64(i)
+		ids[i] = docID
+
+		obj := storobj.FromObject(&models.Object{
+			Class: "Foo",
+			ID:    strfmt.UUID(fmt.Sprintf("00000000-0000-0000-0000-%012d", docID)),
+		}, nil)
+		obj.SetDocID(docID)
+		data, err := obj.MarshalBinary()
+		require.Nil(b, err)
+
+		docIDBytes := make([]byte, 8)
+		binary.LittleEndian.PutUint64(docIDBytes, docID)
+		require.Nil(b, bucket.Put([]byte(obj.ID()), data,
+			lsmkv.WithSecondaryKey(0, docIDBytes)))
+	}
+	require.Nil(b, bucket.FlushAndSwitch())
+
+	b.Run("reused key buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			res, err := ObjectsByDocID(bucket, ids, additional.Properties{})
+			require.Nil(b, err)
+			require.Len(b, res, len(ids))
+		}
+	})
+
+	// baseline: the previous key encoding, which allocated a new buffer per id
+	b.Run("bytes buffer per id", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			res := make([]*storobj.Object, 0, len(ids))
+			for _, id := range ids {
+				keyBuf := bytes.NewBuffer(nil)
+				binary.Write(keyBuf, binary.LittleEndian, &id)
+				data, err := bucket.GetBySecondary(0, keyBuf.Bytes())
+				require.Nil(b, err)
+
+				obj, err := storobj.FromBinaryOptional(data, additional.Properties{})
+				require.Nil(b, err)
+				res = append(res, obj)
+			}
+			require.Len(b, res, len(ids))
+		}
+	})
+}
@@ -0,0 +1,35 @@
+//                           _       _
+// __      _____  __ ___   ___  __ _| |_ ___
+// \ \ /\ / / _ \/ _` \ \ / / |/ _` | __/ _ \
+//  \ V  V /  __/ (_| |\ V /| | (_| | ||  __/
+//   \_/\_/ \___|\__,_| \_/ |_|\__,_|\__\___|
+//
+//  Copyright © 2016 - 2022 SeMI Technologies B.V. All rights reserved.
+//
+//  CONTACT: hello@semi.technology
+//
+
+package inverted
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// objects are written with a binary.Write-encoded doc id as the secondary key
+// (see shard.putObjectLSM), the lookup in objectsByDocID must produce
+// identical keys
+func TestDocIDSecondaryKeyEncoding(t *testing.T) {
+	docIDBytes := make([]byte, 8)
+	for _, id := range []uint64{0, 1, 255, 256, 1 << 32, math.MaxUint64} {
+		keyBuf := bytes.NewBuffer(nil)
+		binary.Write(keyBuf, binary.LittleEndian, &id)
+
+		binary.LittleEndian.PutUint64(docIDBytes, id)
+		assert.Equal(t, keyBuf.Bytes(), docIDBytes)
+	}
+}
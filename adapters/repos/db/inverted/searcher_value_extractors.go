@@ -21,6 +21,10 @@ import (
 	"github.com/semi-technologies/weaviate/adapters/repos/db/helpers"
 )
 
+// ErrEmptySearchTerm is returned when a text or string value does not contain
+// any terms after tokenization, e.g. because it is empty or whitespace-only
+var ErrEmptySearchTerm = errors.New("no search term left after tokenization")
+
 func (fs Searcher) extractTextValue(in interface{}) ([]byte, error) {
 	value, ok := in.(string)
 	if !ok {
@@ -28,6 +32,9 @@ func (fs Searcher) extractTextValue(in interface{}) ([]byte, error) {
 	}
 
 	parts := helpers.TokenizeText(value)
+	if len(parts) == 0 {
+		return nil, errors.Wrapf(ErrEmptySearchTerm, "value %q", value)
+	}
 	if len(parts) > 1 {
 		return nil, fmt.Errorf("expected single search term, got: %v", parts)
 	}
@@ -42,6 +49,9 @@ func (fs Searcher) extractTextValueKeepWildcards(in interface{}) ([]byte, error)
 	}
 
 	parts := helpers.TokenizeTextKeepWildcards(value)
+	if len(parts) == 0 {
+		return nil, errors.Wrapf(ErrEmptySearchTerm, "value %q", value)
+	}
 	if len(parts) > 1 {
 		return nil, fmt.Errorf("expected single search term, got: %v", parts)
 	}
@@ -56,6 +66,9 @@ func (fs Searcher) extractStringValue(in interface{}) ([]byte, error) {
 	}
 
 	parts := helpers.TokenizeString(value)
+	if len(parts) == 0 {
+		return nil, errors.Wrapf(ErrEmptySearchTerm, "value %q", value)
+	}
 	if len(parts) > 1 {
 		return nil, fmt.Errorf("expected single search term, got: %v", parts)
 	}
@@ -0,0 +1,74 @@
+//                           _       _
+// __      _____  __ ___   ___  __ _| |_ ___
+// \ \ /\ / / _ \/ _` \ \ / / |/ _` | __/ _ \
+//  \ V  V /  __/ (_| |\ V /| | (_| | ||  __/
+//   \_/\_/ \___|\__,_| \_/ |_|\__,_|\__\___|
+//
+//  Copyright © 2016 - 2022 SeMI Technologies B.V. All rights reserved.
+//
+//  CONTACT: hello@semi.technology
+//
+
+package inverted
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractValue_EmptySearchTerm(t *testing.T) {
+	fs := Searcher{}
+	extractors := map[string]func(in interface{}) ([]byte, error){
+		"text":                fs.extractTextValue,
+		"text keep wildcards": fs.extractTextValueKeepWildcards,
+		"string":              fs.extractStringValue,
+	}
+
+	for name, extract := range extractors {
+		t.Run(name, func(t *testing.T) {
+			for _, value := range []string{"", " ", "   \t "} {
+				_, err := extract(value)
+				assert.True(t, errors.Is(err, ErrEmptySearchTerm),
+					"value %q: expected ErrEmptySearchTerm, got %v", value, err)
+			}
+
+			res, err := extract(" foo ")
+			assert.Nil(t, err)
+			assert.Equal(t, []byte("foo"), res)
+		})
+	}
+}
+
+func TestExtractTextValue_Numbers(t *testing.T) {
+	res, err := Searcher{}.extractTextValue("350")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("350"), res)
+}
+
+func TestExtractTextValue_UnicodeCasing(t *testing.T) {
+	expected := map[string]string{
+		"İstanbul": "istanbul", // dotted capital I lowers to plain i
+		"Isı":      "isı",      // ASCII I lowers to i, dotless ı is kept
+		"Straße":   "straße",   // ß is kept as is
+		"ΟΔΟΣ":     "οδοσ",     // capital sigma lowers to medial σ
+		"οδος":     "οδος",     // final ς is not folded onto σ
+	}
+
+	for word, token := range expected {
+		t.Run(word, func(t *testing.T) {
+			res, err := Searcher{}.extractTextValue(word)
+			assert.Nil(t, err)
+			assert.Equal(t, []byte(token), res)
+		})
+	}
+
+	t.Run("final sigma does not match capital sigma", func(t *testing.T) {
+		upper, err := Searcher{}.extractTextValue("ΟΔΟΣ")
+		assert.Nil(t, err)
+		lower, err := Searcher{}.extractTextValue("οδος")
+		assert.Nil(t, err)
+		assert.NotEqual(t, upper, lower)
+	})
+}
@@ -12,7 +12,6 @@
 package db
 
 import (
-	"bytes"
 	"context"
 	"encoding/binary"
 	"time"
@@ -213,38 +212,12 @@ func (s *Shard) objectVectorSearch(ctx context.Context, searchVector []float32,
 
 func (s *Shard) objectsByDocID(ids []uint64,
 	additional additional.Properties) ([]*storobj.Object, error) {
-	out := make([]*storobj.Object, len(ids))
-
 	bucket := s.store.Bucket(helpers.ObjectsBucketLSM)
 	if bucket == nil {
-		return nil, errors.Errorf("objects bucket not found")
+		return nil, errors.Wrap(inverted.ErrBucketNotFound, "objects bucket not found")
 	}
 
-	i := 0
-
-	for _, id := range ids {
-		keyBuf := bytes.NewBuffer(nil)
-		binary.Write(keyBuf, binary.LittleEndian, &id)
-		docIDBytes := keyBuf.Bytes()
-		res, err := bucket.GetBySecondary(0, docIDBytes)
-		if err != nil {
-			return nil, err
-		}
-
-		if res == nil {
-			continue
-		}
-
-		unmarshalled, err := storobj.FromBinaryOptional(res, additional)
-		if err != nil {
-			return nil, errors.Wrapf(err, "unmarshal data object at position %d", i)
-		}
-
-		out[i] = unmarshalled
-		i++
-	}
-
-	return out[:i], nil
+	return inverted.ObjectsByDocID(bucket, ids, additional)
 }
 
 func (s *Shard) objectList(ctx context.Context, limit int,
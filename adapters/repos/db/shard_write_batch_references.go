@@ -186,12 +186,14 @@ func (b *referencesBatcher) writeInvertedDeletions(
 	for _, prop := range in {
 		bucket := b.shard.store.Bucket(helpers.BucketFromPropNameLSM(prop.Name))
 		if bucket == nil {
-			return errors.Errorf("no bucket for prop '%s' found", prop.Name)
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no bucket for prop '%s' found", prop.Name)
 		}
 
 		hashBucket := b.shard.store.Bucket(helpers.HashBucketFromPropNameLSM(prop.Name))
 		if hashBucket == nil {
-			return errors.Errorf("no hash bucket for prop '%s' found", prop.Name)
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no hash bucket for prop '%s' found", prop.Name)
 		}
 
 		for _, item := range prop.MergeItems {
@@ -216,12 +218,14 @@ func (b *referencesBatcher) writeInvertedAdditions(
 	for _, prop := range in {
 		bucket := b.shard.store.Bucket(helpers.BucketFromPropNameLSM(prop.Name))
 		if bucket == nil {
-			return errors.Errorf("no bucket for prop '%s' found", prop.Name)
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no bucket for prop '%s' found", prop.Name)
 		}
 
 		hashBucket := b.shard.store.Bucket(helpers.HashBucketFromPropNameLSM(prop.Name))
 		if hashBucket == nil {
-			return errors.Errorf("no hash bucket for prop '%s' found", prop.Name)
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no hash bucket for prop '%s' found", prop.Name)
 		}
 
 		for _, item := range prop.MergeItems {
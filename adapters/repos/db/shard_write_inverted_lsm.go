@@ -26,12 +26,14 @@ func (s *Shard) extendInvertedIndicesLSM(props []inverted.Property,
 	for _, prop := range props {
 		b := s.store.Bucket(helpers.BucketFromPropNameLSM(prop.Name))
 		if b == nil {
-			return errors.Errorf("no bucket for prop '%s' found", prop.Name)
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no bucket for prop '%s' found", prop.Name)
 		}
 
 		hashBucket := s.store.Bucket(helpers.HashBucketFromPropNameLSM(prop.Name))
-		if b == nil {
-			return errors.Errorf("no hash bucket for prop '%s' found", prop.Name)
+		if hashBucket == nil {
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no hash bucket for prop '%s' found", prop.Name)
 		}
 
 		if prop.HasFrequency {
@@ -13,7 +13,6 @@ package db
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/semi-technologies/weaviate/adapters/repos/db/helpers"
@@ -26,12 +25,14 @@ func (s *Shard) deleteFromInvertedIndicesLSM(props []inverted.Property,
 	for _, prop := range props {
 		b := s.store.Bucket(helpers.BucketFromPropNameLSM(prop.Name))
 		if b == nil {
-			return fmt.Errorf("no bucket for prop '%s' found", prop.Name)
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no bucket for prop '%s' found", prop.Name)
 		}
 
 		hashBucket := s.store.Bucket(helpers.HashBucketFromPropNameLSM(prop.Name))
-		if b == nil {
-			return fmt.Errorf("no hash bucket for prop '%s' found", prop.Name)
+		if hashBucket == nil {
+			return errors.Wrapf(inverted.ErrBucketNotFound,
+				"no hash bucket for prop '%s' found", prop.Name)
 		}
 
 		if prop.HasFrequency {
@@ -0,0 +1,74 @@
+//                           _       _
+// __      _____  __ ___   ___  __ _| |_ ___
+// \ \ /\ / / _ \/ _` \ \ / / |/ _` | __/ _ \
+//  \ V  V /  __/ (_| |\ V /| | (_| | ||  __/
+//   \_/\_/ \___|\__,_| \_/ |_|\__,_|\__\___|
+//
+//  Copyright © 2016 - 2022 SeMI Technologies B.V. All rights reserved.
+//
+//  CONTACT: hello@semi.technology
+//
+
+//go:build integrationTest
+// +build integrationTest
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/semi-technologies/weaviate/adapters/repos/db/helpers"
+	"github.com/semi-technologies/weaviate/adapters/repos/db/inverted"
+	"github.com/semi-technologies/weaviate/adapters/repos/db/lsmkv"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShard_InvertedIndexMissingHashBucket(t *testing.T) {
+	dirName := fmt.Sprintf("./testdata/%d", rand.Intn(10000000))
+	os.MkdirAll(dirName, 0o777)
+	defer func() {
+		err := os.RemoveAll(dirName)
+		fmt.Println(err)
+	}()
+
+	logger, _ := test.NewNullLogger()
+	store, err := lsmkv.New(dirName, logger)
+	require.Nil(t, err)
+	defer store.Shutdown(context.Background())
+
+	// only the inverted bucket exists, the matching hash bucket is missing
+	propName := "name"
+	require.Nil(t, store.CreateOrLoadBucket(context.Background(),
+		helpers.BucketFromPropNameLSM(propName),
+		lsmkv.WithStrategy(lsmkv.StrategyMapCollection)))
+
+	shard := &Shard{store: store}
+	props := []inverted.Property{{
+		Name:         propName,
+		HasFrequency: true,
+		Items:        []inverted.Countable{{Data: []byte("foo"), TermFrequency: 1}},
+	}}
+
+	t.Run("extend", func(t *testing.T) {
+		err := shard.extendInvertedIndicesLSM(props, 1)
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, inverted.ErrBucketNotFound))
+		assert.Contains(t, err.Error(),
+			fmt.Sprintf("no hash bucket for prop '%s' found", propName))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := shard.deleteFromInvertedIndicesLSM(props, 1)
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, inverted.ErrBucketNotFound))
+		assert.Contains(t, err.Error(),
+			fmt.Sprintf("no hash bucket for prop '%s' found", propName))
+	})
+}